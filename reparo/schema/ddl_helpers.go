@@ -0,0 +1,35 @@
+package schema
+
+import "github.com/pingcap/parser/ast"
+
+// tableNameFromStmt extracts the single table name a DDL statement targets,
+// for statements that name exactly one table. Statements that can name more
+// than one table (DROP TABLE, RENAME TABLE) are handled by the syncer before
+// reaching the tracker, one ApplyDDL call per affected table.
+func tableNameFromStmt(stmt ast.StmtNode) (table string, ok bool) {
+	switch node := stmt.(type) {
+	case *ast.CreateTableStmt:
+		return node.Table.Name.O, true
+	case *ast.AlterTableStmt:
+		return node.Table.Name.O, true
+	case *ast.DropTableStmt:
+		return node.Tables[0].Name.O, true
+	case *ast.RenameTableStmt:
+		return node.NewTable.Name.O, true
+	case *ast.TruncateTableStmt:
+		return node.Table.Name.O, true
+	case *ast.CreateIndexStmt:
+		return node.Table.Name.O, true
+	case *ast.DropIndexStmt:
+		return node.Table.Name.O, true
+	default:
+		return "", false
+	}
+}
+
+// isDropStmt reports whether stmt removes a table outright, meaning the
+// tracker should forget the table rather than re-cache its definition.
+func isDropStmt(stmt ast.StmtNode) bool {
+	_, ok := stmt.(*ast.DropTableStmt)
+	return ok
+}
@@ -0,0 +1,70 @@
+package schema
+
+import "github.com/pingcap/parser/model"
+
+// Storage persists the table definitions (model.TableInfo) the tracker has
+// applied, so that after a restart Tracker.TableInfo, and the lookups built
+// on it, can still answer for a table whose DDL an earlier process applied.
+// The tracker does not load these definitions back into its in-memory
+// infoschema: ApplyDDL and ShowCreateTable only see tables and databases
+// whose DDL this process has applied.
+type Storage interface {
+	// SaveTable stores (or overwrites) the table info for schema.table.
+	SaveTable(schema, table string, info *model.TableInfo) error
+	// LoadTable returns the previously saved table info, if any.
+	LoadTable(schema, table string) (*model.TableInfo, bool, error)
+	// DeleteTable removes a table, e.g. after a DROP TABLE is applied.
+	DeleteTable(schema, table string) error
+	// DeleteSchema removes every table tracked under schema.
+	DeleteSchema(schema string) error
+	// Close releases any resources held by the storage backend.
+	Close() error
+}
+
+// memoryStorage is the default Storage: it keeps tracked tables in a map and
+// is lost on restart. It's adequate for short-lived reparo runs.
+type memoryStorage struct {
+	tables map[string]map[string]*model.TableInfo
+}
+
+// NewMemoryStorage returns a Storage backed by an in-process map.
+func NewMemoryStorage() Storage {
+	return &memoryStorage{
+		tables: make(map[string]map[string]*model.TableInfo),
+	}
+}
+
+func (s *memoryStorage) SaveTable(schema, table string, info *model.TableInfo) error {
+	tables, ok := s.tables[schema]
+	if !ok {
+		tables = make(map[string]*model.TableInfo)
+		s.tables[schema] = tables
+	}
+	tables[table] = info
+	return nil
+}
+
+func (s *memoryStorage) LoadTable(schema, table string) (*model.TableInfo, bool, error) {
+	tables, ok := s.tables[schema]
+	if !ok {
+		return nil, false, nil
+	}
+	info, ok := tables[table]
+	return info, ok, nil
+}
+
+func (s *memoryStorage) DeleteTable(schema, table string) error {
+	if tables, ok := s.tables[schema]; ok {
+		delete(tables, table)
+	}
+	return nil
+}
+
+func (s *memoryStorage) DeleteSchema(schema string) error {
+	delete(s.tables, schema)
+	return nil
+}
+
+func (s *memoryStorage) Close() error {
+	return nil
+}
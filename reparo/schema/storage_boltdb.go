@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"encoding/json"
+
+	"github.com/etcd-io/bbolt"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/model"
+)
+
+// tablesBucket is the single top-level bucket boltStorage keeps everything
+// under; keys are "<schema>.<table>" and values are the JSON-encoded
+// model.TableInfo, mirroring the layout DM's schema tracker uses.
+var tablesBucket = []byte("tables")
+
+// boltStorage is a Storage backed by a boltdb file, so the table definitions
+// a tracker has saved outlive a reparo restart (see Storage for what a
+// restarted tracker does and does not recover from them).
+type boltStorage struct {
+	db *bbolt.DB
+}
+
+// NewBoltStorage opens (creating if necessary) a boltdb file at path and
+// returns a Storage backed by it.
+func NewBoltStorage(path string) (Storage, error) {
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(tablesBucket)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &boltStorage{db: db}, nil
+}
+
+func tableKey(schema, table string) []byte {
+	return []byte(schema + "." + table)
+}
+
+func (s *boltStorage) SaveTable(schema, table string, info *model.TableInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return errors.Trace(s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(tablesBucket).Put(tableKey(schema, table), data)
+	}))
+}
+
+func (s *boltStorage) LoadTable(schema, table string) (info *model.TableInfo, ok bool, err error) {
+	err = s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(tablesBucket).Get(tableKey(schema, table))
+		if data == nil {
+			return nil
+		}
+		info = new(model.TableInfo)
+		ok = true
+		return json.Unmarshal(data, info)
+	})
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	return
+}
+
+func (s *boltStorage) DeleteTable(schema, table string) error {
+	return errors.Trace(s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(tablesBucket).Delete(tableKey(schema, table))
+	}))
+}
+
+func (s *boltStorage) DeleteSchema(schema string) error {
+	prefix := []byte(schema + ".")
+	return errors.Trace(s.db.Update(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(tablesBucket).Cursor()
+		var keys [][]byte
+		for k, _ := c.Seek(prefix); k != nil && hasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		b := tx.Bucket(tablesBucket)
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func (s *boltStorage) Close() error {
+	return errors.Trace(s.db.Close())
+}
+
+func hasPrefix(k, prefix []byte) bool {
+	if len(k) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if k[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pingcap/parser/model"
+)
+
+func TestBoltStorageSaveLoadDeleteTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tracker.bolt")
+	store, err := NewBoltStorage(path)
+	if err != nil {
+		t.Fatalf("NewBoltStorage returned error: %v", err)
+	}
+	defer store.Close()
+
+	info := &model.TableInfo{Name: model.NewCIStr("t1")}
+	if err := store.SaveTable("test", "t1", info); err != nil {
+		t.Fatalf("SaveTable returned error: %v", err)
+	}
+
+	got, ok, err := store.LoadTable("test", "t1")
+	if err != nil {
+		t.Fatalf("LoadTable returned error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("LoadTable reported not found after SaveTable")
+	}
+	if got.Name.O != "t1" {
+		t.Fatalf("LoadTable returned table named %q, want t1", got.Name.O)
+	}
+
+	if err := store.DeleteTable("test", "t1"); err != nil {
+		t.Fatalf("DeleteTable returned error: %v", err)
+	}
+	if _, ok, err := store.LoadTable("test", "t1"); err != nil || ok {
+		t.Fatalf("LoadTable after DeleteTable = (ok=%v, err=%v), want (false, nil)", ok, err)
+	}
+}
+
+func TestBoltStorageDeleteSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tracker.bolt")
+	store, err := NewBoltStorage(path)
+	if err != nil {
+		t.Fatalf("NewBoltStorage returned error: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.SaveTable("test", "t1", &model.TableInfo{Name: model.NewCIStr("t1")}); err != nil {
+		t.Fatalf("SaveTable t1 returned error: %v", err)
+	}
+	if err := store.SaveTable("test", "t2", &model.TableInfo{Name: model.NewCIStr("t2")}); err != nil {
+		t.Fatalf("SaveTable t2 returned error: %v", err)
+	}
+	if err := store.SaveTable("other", "t1", &model.TableInfo{Name: model.NewCIStr("t1")}); err != nil {
+		t.Fatalf("SaveTable other.t1 returned error: %v", err)
+	}
+
+	if err := store.DeleteSchema("test"); err != nil {
+		t.Fatalf("DeleteSchema returned error: %v", err)
+	}
+
+	if _, ok, _ := store.LoadTable("test", "t1"); ok {
+		t.Errorf("test.t1 still present after DeleteSchema(test)")
+	}
+	if _, ok, _ := store.LoadTable("test", "t2"); ok {
+		t.Errorf("test.t2 still present after DeleteSchema(test)")
+	}
+	if _, ok, err := store.LoadTable("other", "t1"); err != nil || !ok {
+		t.Errorf("other.t1 should survive DeleteSchema(test), got (ok=%v, err=%v)", ok, err)
+	}
+}
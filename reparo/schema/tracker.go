@@ -0,0 +1,230 @@
+// Package schema tracks the upstream schema seen in a binlog stream so that
+// the syncer can reconstruct accurate column names, types and key
+// information for DML events whose pb.Column metadata alone is not enough to
+// answer questions like "which columns make up the PK" or "is this column
+// generated". It mirrors the role go-mysql/canal's schema.Tracker plays for
+// MySQL canal consumers.
+package schema
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/tidb/ddl"
+	"github.com/pingcap/tidb/session"
+	"github.com/pingcap/tidb/store/mockstore"
+)
+
+// Tracker applies DDL statements to an in-memory TiDB infoschema and answers
+// column/key questions for the tables it has seen. It is not safe for
+// concurrent use; callers must serialize access (the syncer already
+// processes binlogs in order).
+type Tracker struct {
+	se    session.Session
+	store Storage
+}
+
+// NewTracker creates a Tracker backed by store for persistence. A nil store
+// is replaced with an in-memory Storage, matching the syncer's existing
+// behavior of keeping no state across restarts.
+func NewTracker(store Storage) (*Tracker, error) {
+	if store == nil {
+		store = NewMemoryStorage()
+	}
+
+	// an in-memory TiKV store is enough to drive DDL execution and build an
+	// infoschema.InfoSchema; reparo never issues DML through it, only DDL
+	// replay to keep column/key metadata current.
+	kvStore, err := mockstore.NewMockStore()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, err := session.BootstrapSession(kvStore); err != nil {
+		return nil, errors.Trace(err)
+	}
+	se, err := session.CreateSession(kvStore)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &Tracker{
+		se:    se,
+		store: store,
+	}, nil
+}
+
+// ApplyDDL parses and applies a single DDL statement against schema,
+// updating the tracker's infoschema and persisting the resulting table
+// definition to the configured Storage. sql is the raw form a pb.Binlog
+// ships in DdlQuery ("use <db>; create ..." or a bare single statement);
+// the leading USE, if present, is skipped rather than required to already
+// be stripped.
+func (t *Tracker) ApplyDDL(schema, sql string) error {
+	stmtNode, err := singleDDLStmt(sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// CREATE DATABASE (and DROP DATABASE, which removes schema outright)
+	// target a schema that isn't guaranteed to exist once this statement
+	// runs, so `use` would fail before ExecuteDDLStatement ever gets a
+	// chance to create it; skip it for those two statement kinds.
+	switch stmtNode.(type) {
+	case *ast.CreateDatabaseStmt, *ast.DropDatabaseStmt:
+	default:
+		if _, err := t.se.Execute(context.Background(), "use `"+schema+"`"); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if err := ddl.ExecuteDDLStatement(t.se, stmtNode); err != nil {
+		return errors.Trace(err)
+	}
+
+	if _, ok := stmtNode.(*ast.DropDatabaseStmt); ok {
+		return errors.Trace(t.store.DeleteSchema(schema))
+	}
+
+	table, ok := tableNameFromStmt(stmtNode)
+	if !ok {
+		// statements such as CREATE/DROP DATABASE don't target a single
+		// table; nothing further to cache.
+		return nil
+	}
+
+	if isDropStmt(stmtNode) {
+		return errors.Trace(t.store.DeleteTable(schema, table))
+	}
+
+	info, err := t.TableInfo(schema, table)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(t.store.SaveTable(schema, table, info))
+}
+
+// singleDDLStmt parses sql and returns the one non-USE statement it
+// contains, tolerating both the bare single-statement form and the
+// "use <db>; <ddl>" form a pb.Binlog's DdlQuery ships.
+func singleDDLStmt(sql string) (ast.StmtNode, error) {
+	stmts, _, err := parser.New().Parse(sql, "", "")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var ddlStmt ast.StmtNode
+	for _, stmt := range stmts {
+		if _, ok := stmt.(*ast.UseStmt); ok {
+			continue
+		}
+		if ddlStmt != nil {
+			return nil, errors.Errorf("more than one ddl statement in %q", sql)
+		}
+		ddlStmt = stmt
+	}
+	if ddlStmt == nil {
+		return nil, errors.Errorf("no ddl statement found in %q", sql)
+	}
+	return ddlStmt, nil
+}
+
+// TableInfo returns the current TiDB model.TableInfo for schema.table, as
+// last observed from DDL, falling back to the persisted Storage copy if the
+// tracker hasn't seen that table in this process's lifetime yet.
+func (t *Tracker) TableInfo(schema, table string) (*model.TableInfo, error) {
+	is := t.se.GetInfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr(schema), model.NewCIStr(table))
+	if err == nil {
+		return tbl.Meta(), nil
+	}
+
+	info, ok, loadErr := t.store.LoadTable(schema, table)
+	if loadErr != nil {
+		return nil, errors.Trace(loadErr)
+	}
+	if !ok {
+		return nil, errors.Errorf("schema tracker: table %s.%s not found", schema, table)
+	}
+	return info, nil
+}
+
+// Columns returns the ordered column definitions of schema.table.
+func (t *Tracker) Columns(schema, table string) ([]*model.ColumnInfo, error) {
+	info, err := t.TableInfo(schema, table)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return info.Columns, nil
+}
+
+// ShowCreateTable renders the CREATE TABLE statement for schema.table as
+// currently tracked, so a caller can replay it against a downstream that's
+// missing the table, e.g. under MissingTablePolicyAutoCreate.
+func (t *Tracker) ShowCreateTable(schema, table string) (string, error) {
+	rs, err := t.se.Execute(context.Background(), "show create table `"+schema+"`.`"+table+"`")
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if len(rs) == 0 {
+		return "", errors.Errorf("schema tracker: show create table returned no result for %s.%s", schema, table)
+	}
+
+	row, err := rs[0].Next(nil)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if row == nil {
+		return "", errors.Errorf("schema tracker: show create table returned no rows for %s.%s", schema, table)
+	}
+	return row.GetString(1), nil
+}
+
+// IsGeneratedColumn reports whether schema.table.column is a generated
+// column, which callers must exclude from INSERT/UPDATE column lists.
+func (t *Tracker) IsGeneratedColumn(schema, table, column string) (bool, error) {
+	cols, err := t.Columns(schema, table)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	for _, c := range cols {
+		if c.Name.O == column {
+			return c.GeneratedExprString != "", nil
+		}
+	}
+	return false, errors.Errorf("schema tracker: column %s not found in %s.%s", column, schema, table)
+}
+
+// PrimaryKeyColumns returns the column names making up schema.table's PK (or,
+// when it has none, its first unique key), in the key's column order, so
+// callers can build a safe WHERE clause for UPDATE/DELETE. It returns nil
+// when the table has neither.
+func (t *Tracker) PrimaryKeyColumns(schema, table string) ([]string, error) {
+	info, err := t.TableInfo(schema, table)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	if pk := info.GetPkColInfo(); pk != nil {
+		return []string{pk.Name.O}, nil
+	}
+	for _, primaryOnly := range []bool{true, false} {
+		for _, idx := range info.Indices {
+			if idx.Primary || (!primaryOnly && idx.Unique) {
+				cols := make([]string, 0, len(idx.Columns))
+				for _, c := range idx.Columns {
+					cols = append(cols, c.Name.O)
+				}
+				return cols, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Close releases the tracker's underlying storage and session resources.
+func (t *Tracker) Close() error {
+	t.se.Close()
+	return errors.Trace(t.store.Close())
+}
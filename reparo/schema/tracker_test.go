@@ -0,0 +1,53 @@
+package schema
+
+import "testing"
+
+func TestApplyDDLCreateDatabase(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("NewTracker returned error: %v", err)
+	}
+	defer tracker.Close()
+
+	if err := tracker.ApplyDDL("newdb", "create database `newdb`"); err != nil {
+		t.Fatalf("ApplyDDL(CREATE DATABASE) returned error: %v", err)
+	}
+
+	if err := tracker.ApplyDDL("newdb", "use `newdb`; create table `t1` (`id` int primary key)"); err != nil {
+		t.Fatalf("ApplyDDL(CREATE TABLE) after CREATE DATABASE returned error: %v", err)
+	}
+
+	if _, err := tracker.TableInfo("newdb", "t1"); err != nil {
+		t.Fatalf("TableInfo after CREATE TABLE returned error: %v", err)
+	}
+}
+
+func TestApplyDDLDropDatabase(t *testing.T) {
+	store := NewMemoryStorage()
+	tracker, err := NewTracker(store)
+	if err != nil {
+		t.Fatalf("NewTracker returned error: %v", err)
+	}
+	defer tracker.Close()
+
+	if err := tracker.ApplyDDL("dropdb", "create database `dropdb`"); err != nil {
+		t.Fatalf("ApplyDDL(CREATE DATABASE) returned error: %v", err)
+	}
+	if err := tracker.ApplyDDL("dropdb", "use `dropdb`; create table `t1` (`id` int primary key)"); err != nil {
+		t.Fatalf("ApplyDDL(CREATE TABLE) returned error: %v", err)
+	}
+	if _, ok, err := store.LoadTable("dropdb", "t1"); err != nil || !ok {
+		t.Fatalf("LoadTable before DROP DATABASE = (ok=%v, err=%v), want (true, nil)", ok, err)
+	}
+
+	if err := tracker.ApplyDDL("dropdb", "drop database `dropdb`"); err != nil {
+		t.Fatalf("ApplyDDL(DROP DATABASE) returned error: %v", err)
+	}
+
+	// DROP DATABASE must forget every table cached under it, or a stale
+	// TableInfo survives in Storage and TableInfo's fallback path (tracker.go)
+	// returns wrong column/PK info for a later table of the same name.
+	if _, ok, err := store.LoadTable("dropdb", "t1"); err != nil || ok {
+		t.Fatalf("LoadTable after DROP DATABASE = (ok=%v, err=%v), want (false, nil)", ok, err)
+	}
+}
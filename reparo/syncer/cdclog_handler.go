@@ -0,0 +1,83 @@
+package syncer
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb-binlog/pkg/loader"
+	"github.com/pingcap/tidb-binlog/reparo/schema"
+)
+
+// CDCLogEventHandler is an EventHandler that turns each DML into a
+// RowChange, encodes it (JSON or Avro), and writes it to a rotating
+// cdclogWriter instead of applying it to a downstream database. No
+// command-line option selects it; callers build it with
+// NewCDCLogEventHandler and pass it to Dispatch.
+type CDCLogEventHandler struct {
+	DummyEventHandler
+
+	tracker *schema.Tracker
+	encoder RowChangeEncoder
+	writer  cdclogWriter
+}
+
+// NewCDCLogEventHandler returns an EventHandler that writes every DML it
+// sees through encoder to writer. tracker supplies the column types
+// RowChange.ColumnTypes records; it may be nil, but then AvroEncoder.Encode
+// will refuse any DML for a table the tracker hasn't seen, since ColumnTypes
+// would otherwise be empty despite row data being present.
+func NewCDCLogEventHandler(tracker *schema.Tracker, encoder RowChangeEncoder, writer cdclogWriter) *CDCLogEventHandler {
+	return &CDCLogEventHandler{tracker: tracker, encoder: encoder, writer: writer}
+}
+
+// OnInsert implements EventHandler.
+func (h *CDCLogEventHandler) OnInsert(dml *loader.DML, commitTS int64) error {
+	return h.write(RowChangeInsert, dml.Database, dml.Table, commitTS, nil, dml.Values)
+}
+
+// OnUpdate implements EventHandler.
+func (h *CDCLogEventHandler) OnUpdate(dml *loader.DML, commitTS int64) error {
+	return h.write(RowChangeUpdate, dml.Database, dml.Table, commitTS, dml.OldValues, dml.Values)
+}
+
+// OnDelete implements EventHandler.
+func (h *CDCLogEventHandler) OnDelete(dml *loader.DML, commitTS int64) error {
+	return h.write(RowChangeDelete, dml.Database, dml.Table, commitTS, dml.Values, nil)
+}
+
+func (h *CDCLogEventHandler) write(op RowChangeOp, database, table string, commitTS int64, pre, post map[string]interface{}) error {
+	rc := &RowChange{
+		Op:          op,
+		Schema:      database,
+		Table:       table,
+		CommitTS:    commitTS,
+		PreImage:    pre,
+		PostImage:   post,
+		ColumnTypes: h.columnTypes(database, table),
+	}
+
+	data, err := h.encoder.Encode(rc)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return errors.Trace(h.writer.Write(rc.Subject(), data))
+}
+
+func (h *CDCLogEventHandler) columnTypes(database, table string) map[string]string {
+	if h.tracker == nil {
+		return nil
+	}
+
+	cols, err := h.tracker.Columns(database, table)
+	if err != nil {
+		return nil
+	}
+
+	types := make(map[string]string, len(cols))
+	for _, c := range cols {
+		types[c.Name.O] = c.FieldType.CompactStr()
+	}
+	return types
+}
+
+// String implements EventHandler.
+func (h *CDCLogEventHandler) String() string { return "CDCLogEventHandler" }
@@ -0,0 +1,53 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb-binlog/pkg/loader"
+)
+
+// recordingEncoder captures the RowChange passed to Encode without
+// serializing it, so tests can assert on its fields directly.
+type recordingEncoder struct {
+	last *RowChange
+}
+
+func (e *recordingEncoder) Encode(rc *RowChange) ([]byte, error) {
+	e.last = rc
+	return nil, nil
+}
+
+func (e *recordingEncoder) FileExtension() string { return ".test" }
+
+// discardWriter is a cdclogWriter that drops everything written to it.
+type discardWriter struct{}
+
+func (discardWriter) Write(subject string, data []byte) error { return nil }
+func (discardWriter) Close() error                             { return nil }
+
+func TestCDCLogEventHandlerCommitTSPerCall(t *testing.T) {
+	enc := &recordingEncoder{}
+	h := NewCDCLogEventHandler(nil, enc, discardWriter{})
+
+	dml := &loader.DML{Database: "test", Table: "t1", Values: map[string]interface{}{"id": 1}}
+
+	if err := h.OnInsert(dml, 100); err != nil {
+		t.Fatalf("OnInsert returned error: %v", err)
+	}
+	if enc.last.CommitTS != 100 {
+		t.Fatalf("first OnInsert: CommitTS = %d, want 100", enc.last.CommitTS)
+	}
+
+	if err := h.OnXID(100); err != nil {
+		t.Fatalf("OnXID returned error: %v", err)
+	}
+
+	// a second txn's DML must carry its own commit ts, not the first txn's,
+	// even though OnXID for the first txn already ran.
+	if err := h.OnInsert(dml, 200); err != nil {
+		t.Fatalf("second OnInsert returned error: %v", err)
+	}
+	if enc.last.CommitTS != 200 {
+		t.Fatalf("second OnInsert: CommitTS = %d, want 200", enc.last.CommitTS)
+	}
+}
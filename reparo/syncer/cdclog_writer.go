@@ -0,0 +1,100 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pingcap/errors"
+)
+
+// cdclogWriter is where a CDCLogEventHandler rotates its encoded records
+// to. Implementations cover local disk and S3.
+type cdclogWriter interface {
+	// Write appends data, belonging to subject, as one record, verbatim and
+	// with no separator added: data must already be self-framed (see
+	// RowChangeEncoder). A writer decides on its own rotation policy (by
+	// size, by count, ...).
+	Write(subject string, data []byte) error
+	Close() error
+}
+
+// localFileWriter rotates cdclog records into files under dir, one
+// subdirectory per table subject, rolling over to a new file every
+// maxRecords records so no single file grows unbounded.
+type localFileWriter struct {
+	dir         string
+	ext         string
+	maxRecords  int
+	files       map[string]*os.File
+	recordCount map[string]int
+	fileIndex   map[string]int
+}
+
+// newLocalFileWriter returns a cdclogWriter that writes rotating files with
+// extension ext under dir, rolling to a new file after maxRecords records.
+func newLocalFileWriter(dir, ext string, maxRecords int) (cdclogWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &localFileWriter{
+		dir:         dir,
+		ext:         ext,
+		maxRecords:  maxRecords,
+		files:       make(map[string]*os.File),
+		recordCount: make(map[string]int),
+		fileIndex:   make(map[string]int),
+	}, nil
+}
+
+func (w *localFileWriter) Write(subject string, data []byte) error {
+	f, err := w.fileFor(subject)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		return errors.Trace(err)
+	}
+
+	w.recordCount[subject]++
+	if w.recordCount[subject] >= w.maxRecords {
+		if err := f.Close(); err != nil {
+			return errors.Trace(err)
+		}
+		delete(w.files, subject)
+		w.recordCount[subject] = 0
+		w.fileIndex[subject]++
+	}
+	return nil
+}
+
+func (w *localFileWriter) fileFor(subject string) (*os.File, error) {
+	if f, ok := w.files[subject]; ok {
+		return f, nil
+	}
+
+	subDir := filepath.Join(w.dir, subject)
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	name := filepath.Join(subDir, fmt.Sprintf("%d%s", w.fileIndex[subject], w.ext))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	w.files[subject] = f
+	return f, nil
+}
+
+func (w *localFileWriter) Close() error {
+	for _, f := range w.files {
+		if err := f.Close(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package syncer
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pingcap/errors"
+)
+
+// s3Writer rotates cdclog records into objects under a bucket/prefix, one
+// PutObject per maxRecords records per subject, mirroring localFileWriter's
+// rotation policy so the two destinations behave the same way from the
+// CDCLogEventHandler's point of view.
+type s3Writer struct {
+	client      *s3.S3
+	bucket      string
+	prefix      string
+	ext         string
+	maxRecords  int
+	buffers     map[string]*bytes.Buffer
+	recordCount map[string]int
+	objectIndex map[string]int
+}
+
+// newS3Writer returns a cdclogWriter that uploads rotating objects named
+// "<prefix>/<subject>/<index><ext>" to bucket.
+func newS3Writer(client *s3.S3, bucket, prefix, ext string, maxRecords int) cdclogWriter {
+	return &s3Writer{
+		client:      client,
+		bucket:      bucket,
+		prefix:      prefix,
+		ext:         ext,
+		maxRecords:  maxRecords,
+		buffers:     make(map[string]*bytes.Buffer),
+		recordCount: make(map[string]int),
+		objectIndex: make(map[string]int),
+	}
+}
+
+func (w *s3Writer) Write(subject string, data []byte) error {
+	buf, ok := w.buffers[subject]
+	if !ok {
+		buf = new(bytes.Buffer)
+		w.buffers[subject] = buf
+	}
+
+	buf.Write(data)
+	w.recordCount[subject]++
+
+	if w.recordCount[subject] >= w.maxRecords {
+		return errors.Trace(w.flush(subject))
+	}
+	return nil
+}
+
+func (w *s3Writer) flush(subject string) error {
+	buf := w.buffers[subject]
+	if buf == nil || buf.Len() == 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s/%s/%d%s", w.prefix, subject, w.objectIndex[subject], w.ext)
+	_, err := w.client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(w.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(buf.Bytes()),
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	buf.Reset()
+	w.recordCount[subject] = 0
+	w.objectIndex[subject]++
+	return nil
+}
+
+func (w *s3Writer) Close() error {
+	for subject := range w.buffers {
+		if err := w.flush(subject); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
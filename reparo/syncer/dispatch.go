@@ -0,0 +1,61 @@
+package syncer
+
+import (
+	"strconv"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb-binlog/pkg/loader"
+	pb "github.com/pingcap/tidb-binlog/proto/binlog"
+	"github.com/pingcap/tidb-binlog/reparo/schema"
+)
+
+// Dispatch decodes binlog via pbBinlogToTxn and replays every resulting
+// loader.DDL/DML through handler, so embedders of this package can plug in
+// a sink other than loader.Loader (Kafka, Elasticsearch, a webhook, Redis,
+// ...) without touching the decode logic. Pass NewLoaderEventHandler(ld) as
+// handler to get today's "push straight into the MySQL loader" behavior.
+// missingTable may be nil to disable MissingTablePolicy handling. observer
+// may be nil to skip the OnTableChanged/OnPosSynced notifications.
+func Dispatch(binlog *pb.Binlog, tracker *schema.Tracker, missingTable *MissingTableOptions, handler EventHandler, observer Observer) error {
+	txns, err := pbBinlogToTxn(binlog, tracker, missingTable)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	for _, txn := range txns {
+		if txn.DDL != nil {
+			if err := handler.OnDDL(txn.DDL); err != nil {
+				return errors.Trace(err)
+			}
+			if observer != nil && txn.DDL.Table != "" {
+				observer.OnTableChanged(txn.DDL.Database, txn.DDL.Table)
+			}
+		}
+
+		for _, dml := range txn.DMLs {
+			var err error
+			switch dml.Tp {
+			case loader.InsertDMLType:
+				err = handler.OnInsert(dml, binlog.CommitTs)
+			case loader.UpdateDMLType:
+				err = handler.OnUpdate(dml, binlog.CommitTs)
+			case loader.DeleteDMLType:
+				err = handler.OnDelete(dml, binlog.CommitTs)
+			default:
+				err = errors.Errorf("unknown dml type: %v", dml.Tp)
+			}
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+
+		if err := handler.OnXID(binlog.CommitTs); err != nil {
+			return errors.Trace(err)
+		}
+		if observer != nil {
+			observer.OnPosSynced(strconv.FormatInt(binlog.CommitTs, 10))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb-binlog/pkg/loader"
+	pb "github.com/pingcap/tidb-binlog/proto/binlog"
+)
+
+// recordingHandler is an EventHandler that records every call it receives,
+// for assertions in Dispatch tests.
+type recordingHandler struct {
+	DummyEventHandler
+
+	ddls []*loader.DDL
+}
+
+func (h *recordingHandler) OnDDL(ddl *loader.DDL) error {
+	h.ddls = append(h.ddls, ddl)
+	return nil
+}
+
+// recordingObserver is an Observer that records every call it receives.
+type recordingObserver struct {
+	tableChanges [][2]string
+	posSynced    []string
+}
+
+func (o *recordingObserver) OnTableChanged(schema, table string) {
+	o.tableChanges = append(o.tableChanges, [2]string{schema, table})
+}
+
+func (o *recordingObserver) OnPosSynced(pos string) {
+	o.posSynced = append(o.posSynced, pos)
+}
+
+func TestDispatchNotifiesObserverOnDDL(t *testing.T) {
+	handler := &recordingHandler{}
+	observer := &recordingObserver{}
+
+	binlog := &pb.Binlog{
+		Tp:       pb.BinlogType_DDL,
+		DdlQuery: []byte("use `test`; create table `t1` (`id` int primary key)"),
+		CommitTs: 42,
+	}
+
+	if err := Dispatch(binlog, nil, nil, handler, observer); err != nil {
+		t.Fatalf("Dispatch returned error: %v", err)
+	}
+
+	if len(handler.ddls) != 1 {
+		t.Fatalf("got %d OnDDL calls, want 1", len(handler.ddls))
+	}
+	if want := [2]string{"test", "t1"}; len(observer.tableChanges) != 1 || observer.tableChanges[0] != want {
+		t.Fatalf("OnTableChanged calls = %v, want [%v]", observer.tableChanges, want)
+	}
+	if len(observer.posSynced) != 1 || observer.posSynced[0] != "42" {
+		t.Fatalf("OnPosSynced calls = %v, want [\"42\"]", observer.posSynced)
+	}
+}
+
+func TestDispatchToleratesNilObserver(t *testing.T) {
+	handler := &recordingHandler{}
+
+	binlog := &pb.Binlog{
+		Tp:       pb.BinlogType_DDL,
+		DdlQuery: []byte("use `test`; create table `t1` (`id` int primary key)"),
+		CommitTs: 1,
+	}
+
+	if err := Dispatch(binlog, nil, nil, handler, nil); err != nil {
+		t.Fatalf("Dispatch with nil observer returned error: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package syncer
+
+import "github.com/pingcap/tidb-binlog/pkg/loader"
+
+// EventHandler lets a caller embed this package as a library and react to
+// each decoded binlog event directly, instead of being locked into
+// loader.Loader as the only possible sink. It's modeled on go-mysql canal's
+// EventHandler, so writing a Kafka/Elasticsearch/webhook/Redis sink on top
+// of tidb-binlog follows the same shape consumers of that library already
+// know.
+type EventHandler interface {
+	// OnDDL is called once per affected table for a DDL binlog; a DDL
+	// naming more than one table (see parseSchemaTablesFromDDL) results in
+	// one OnDDL call per table.
+	OnDDL(ddl *loader.DDL) error
+	// OnInsert, OnUpdate and OnDelete carry commitTS of the txn the DML
+	// belongs to, since OnXID for that txn hasn't fired yet when these are
+	// called.
+	OnInsert(dml *loader.DML, commitTS int64) error
+	OnUpdate(dml *loader.DML, commitTS int64) error
+	OnDelete(dml *loader.DML, commitTS int64) error
+	// OnXID is called once a txn commits, carrying its commit ts, so a
+	// handler can checkpoint.
+	OnXID(commitTS int64) error
+	// String identifies the handler, e.g. for logging which sink is active.
+	String() string
+}
+
+// Observer is notified of syncer-internal state changes that aren't
+// themselves binlog events, mirroring go-mysql canal's Observer. Dispatch
+// accepts an optional Observer and drives both methods itself; a nil
+// Observer disables this entirely.
+type Observer interface {
+	// OnTableChanged is called once per table named in a dispatched DDL,
+	// after EventHandler.OnDDL has been called for it.
+	OnTableChanged(schema, table string)
+	// OnPosSynced is called once a txn's commit ts has been dispatched to
+	// EventHandler.OnXID, so the observer can advance its own checkpoint.
+	// pos is the txn's commit ts, formatted as a decimal string.
+	OnPosSynced(pos string)
+}
+
+// DummyEventHandler is a no-op EventHandler. Embed it to pick up defaults
+// for the methods a custom handler doesn't care about.
+type DummyEventHandler struct{}
+
+// OnDDL implements EventHandler.
+func (h *DummyEventHandler) OnDDL(*loader.DDL) error { return nil }
+
+// OnInsert implements EventHandler.
+func (h *DummyEventHandler) OnInsert(*loader.DML, int64) error { return nil }
+
+// OnUpdate implements EventHandler.
+func (h *DummyEventHandler) OnUpdate(*loader.DML, int64) error { return nil }
+
+// OnDelete implements EventHandler.
+func (h *DummyEventHandler) OnDelete(*loader.DML, int64) error { return nil }
+
+// OnXID implements EventHandler.
+func (h *DummyEventHandler) OnXID(int64) error { return nil }
+
+// String implements EventHandler.
+func (h *DummyEventHandler) String() string { return "DummyEventHandler" }
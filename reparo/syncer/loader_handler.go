@@ -0,0 +1,62 @@
+package syncer
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb-binlog/pkg/loader"
+)
+
+// LoaderEventHandler adapts the existing loader.Loader sink to the
+// EventHandler interface. Passing it to Dispatch preserves today's behavior
+// of pushing each binlog's txn onto ld; Dispatch has no default handler of
+// its own.
+type LoaderEventHandler struct {
+	DummyEventHandler
+
+	ld  *loader.Loader
+	txn *loader.Txn
+}
+
+// NewLoaderEventHandler returns an EventHandler that feeds decoded events
+// into ld, the same way the syncer did before EventHandler existed.
+func NewLoaderEventHandler(ld *loader.Loader) *LoaderEventHandler {
+	return &LoaderEventHandler{ld: ld, txn: new(loader.Txn)}
+}
+
+// OnDDL implements EventHandler.
+func (h *LoaderEventHandler) OnDDL(ddl *loader.DDL) error {
+	h.txn.DDL = ddl
+	return nil
+}
+
+// OnInsert implements EventHandler.
+func (h *LoaderEventHandler) OnInsert(dml *loader.DML, commitTS int64) error {
+	h.txn.DMLs = append(h.txn.DMLs, dml)
+	return nil
+}
+
+// OnUpdate implements EventHandler.
+func (h *LoaderEventHandler) OnUpdate(dml *loader.DML, commitTS int64) error {
+	h.txn.DMLs = append(h.txn.DMLs, dml)
+	return nil
+}
+
+// OnDelete implements EventHandler.
+func (h *LoaderEventHandler) OnDelete(dml *loader.DML, commitTS int64) error {
+	h.txn.DMLs = append(h.txn.DMLs, dml)
+	return nil
+}
+
+// OnXID implements EventHandler by pushing the accumulated txn to the
+// loader and starting a fresh one for the next commit.
+func (h *LoaderEventHandler) OnXID(commitTS int64) error {
+	select {
+	case h.ld.Input() <- h.txn:
+	case err := <-h.ld.Successes():
+		return errors.Trace(err)
+	}
+	h.txn = new(loader.Txn)
+	return nil
+}
+
+// String implements EventHandler.
+func (h *LoaderEventHandler) String() string { return "LoaderEventHandler" }
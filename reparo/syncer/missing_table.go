@@ -0,0 +1,59 @@
+package syncer
+
+import "github.com/pingcap/errors"
+
+// MissingTablePolicy controls what pbBinlogToTxn does with a DML event for
+// a table DownstreamSchemaChecker reports as absent downstream, e.g.
+// because the table was added upstream mid-stream, or a dump only covered
+// part of the schema. It borrows its shape from DM's
+// ErrSyncerDownstreamTableNotFound handling.
+type MissingTablePolicy int
+
+// The supported MissingTablePolicy values.
+const (
+	// MissingTablePolicyError fails the binlog (the default): callers must
+	// fix the downstream schema before the syncer can make progress.
+	MissingTablePolicyError MissingTablePolicy = iota
+	// MissingTablePolicySkip drops the offending DML and continues.
+	MissingTablePolicySkip
+	// MissingTablePolicyAutoCreate emits a synthetic loader.DDL, derived
+	// from the schema tracker's cached CREATE TABLE, ahead of the DML's
+	// txn, then applies the DML as usual.
+	MissingTablePolicyAutoCreate
+)
+
+// ParseMissingTablePolicy parses a policy name: "error" (or ""), "skip" or
+// "auto-create-from-tracker".
+func ParseMissingTablePolicy(s string) (MissingTablePolicy, error) {
+	switch s {
+	case "", "error":
+		return MissingTablePolicyError, nil
+	case "skip":
+		return MissingTablePolicySkip, nil
+	case "auto-create-from-tracker":
+		return MissingTablePolicyAutoCreate, nil
+	default:
+		return 0, errors.Errorf("unknown missing-table-policy: %s", s)
+	}
+}
+
+// DownstreamSchemaChecker reports whether the downstream already has a
+// counterpart for an upstream table, so pbBinlogToTxn can apply
+// MissingTablePolicy instead of letting the loader fail on an unresolvable
+// "table doesn't exist" error from the downstream database.
+type DownstreamSchemaChecker interface {
+	TableExists(schema, table string) bool
+}
+
+// MissingTableOptions bundles the checker and policy pbBinlogToTxn needs to
+// detect and react to a missing downstream table. A nil *MissingTableOptions
+// (or a nil Checker) disables the check entirely, same as before it existed.
+type MissingTableOptions struct {
+	Checker DownstreamSchemaChecker
+	Policy  MissingTablePolicy
+}
+
+// errDownstreamTableNotFound is returned (wrapped with schema/table context)
+// when MissingTablePolicy is MissingTablePolicyError and the downstream
+// lacks a table the upstream just wrote to.
+var errDownstreamTableNotFound = errors.New("downstream table not found")
@@ -0,0 +1,178 @@
+package syncer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/tidb-binlog/pkg/loader"
+	pb "github.com/pingcap/tidb-binlog/proto/binlog"
+	"github.com/pingcap/tidb-binlog/reparo/schema"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/codec"
+)
+
+func TestParseMissingTablePolicy(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    MissingTablePolicy
+		wantErr bool
+	}{
+		{"", MissingTablePolicyError, false},
+		{"error", MissingTablePolicyError, false},
+		{"skip", MissingTablePolicySkip, false},
+		{"auto-create-from-tracker", MissingTablePolicyAutoCreate, false},
+		{"bogus", 0, true},
+	}
+
+	for _, c := range cases {
+		got, err := ParseMissingTablePolicy(c.in)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("ParseMissingTablePolicy(%q) returned nil error, want error", c.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMissingTablePolicy(%q) returned error: %v", c.in, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("ParseMissingTablePolicy(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+// fakeSchemaChecker is a DownstreamSchemaChecker test double reporting a
+// table as present only if it's been explicitly listed.
+type fakeSchemaChecker struct {
+	existing map[string]bool
+}
+
+func (c *fakeSchemaChecker) TableExists(schema, table string) bool {
+	return c.existing[schema+"."+table]
+}
+
+// encodedIntColumn returns a marshaled pb.Column holding an int value, in
+// the form pbBinlogToTxn's genColsAndArgs expects to find in a DML event's
+// Row.
+func encodedIntColumn(t *testing.T, name string, value int64) []byte {
+	t.Helper()
+
+	encoded, err := codec.EncodeValue(&stmtctx.StatementContext{}, nil, types.NewIntDatum(value))
+	if err != nil {
+		t.Fatalf("codec.EncodeValue returned error: %v", err)
+	}
+
+	col := &pb.Column{Name: name, Tp: []byte{mysql.TypeLong}, Value: encoded, MysqlType: "int"}
+	data, err := col.Marshal()
+	if err != nil {
+		t.Fatalf("Column.Marshal returned error: %v", err)
+	}
+	return data
+}
+
+func insertBinlog(schemaName, table string, commitTS int64, rows ...[]byte) *pb.Binlog {
+	events := make([]*pb.Event, len(rows))
+	for i, row := range rows {
+		events[i] = &pb.Event{SchemaName: schemaName, TableName: table, Tp: pb.EventType_Insert, Row: [][]byte{row}}
+	}
+	return &pb.Binlog{Tp: pb.BinlogType_DML, DmlData: &pb.DMLData{Events: events}, CommitTs: commitTS}
+}
+
+func TestPbBinlogToTxnMissingTablePolicySkip(t *testing.T) {
+	binlog := &pb.Binlog{
+		Tp:       pb.BinlogType_DML,
+		DmlData:  &pb.DMLData{Events: []*pb.Event{{SchemaName: "test", TableName: "missing", Tp: pb.EventType_Insert}}},
+		CommitTs: 1,
+	}
+	missingTable := &MissingTableOptions{Checker: &fakeSchemaChecker{existing: map[string]bool{}}, Policy: MissingTablePolicySkip}
+
+	txns, err := pbBinlogToTxn(binlog, nil, missingTable)
+	if err != nil {
+		t.Fatalf("pbBinlogToTxn returned error: %v", err)
+	}
+	if len(txns) != 1 || len(txns[0].DMLs) != 0 {
+		t.Fatalf("pbBinlogToTxn with MissingTablePolicySkip = %d txns, first carrying %d DMLs; want 1 txn with the event dropped", len(txns), len(txns[0].DMLs))
+	}
+}
+
+func TestPbBinlogToTxnMissingTablePolicyError(t *testing.T) {
+	binlog := &pb.Binlog{
+		Tp:       pb.BinlogType_DML,
+		DmlData:  &pb.DMLData{Events: []*pb.Event{{SchemaName: "test", TableName: "missing", Tp: pb.EventType_Insert}}},
+		CommitTs: 1,
+	}
+	missingTable := &MissingTableOptions{Checker: &fakeSchemaChecker{existing: map[string]bool{}}, Policy: MissingTablePolicyError}
+
+	_, err := pbBinlogToTxn(binlog, nil, missingTable)
+	if err == nil || !strings.Contains(err.Error(), errDownstreamTableNotFound.Error()) {
+		t.Fatalf("pbBinlogToTxn with MissingTablePolicyError returned %v, want an error wrapping %q", err, errDownstreamTableNotFound)
+	}
+}
+
+func TestPbBinlogToTxnMissingTablePolicyAutoCreate(t *testing.T) {
+	tracker, err := schema.NewTracker(nil)
+	if err != nil {
+		t.Fatalf("schema.NewTracker returned error: %v", err)
+	}
+	defer tracker.Close()
+	if err := tracker.ApplyDDL("test", "create database `test`"); err != nil {
+		t.Fatalf("ApplyDDL(CREATE DATABASE) returned error: %v", err)
+	}
+	if err := tracker.ApplyDDL("test", "use `test`; create table `t1` (`id` int primary key)"); err != nil {
+		t.Fatalf("ApplyDDL(CREATE TABLE) returned error: %v", err)
+	}
+
+	binlog := insertBinlog("test", "t1", 1, encodedIntColumn(t, "id", 1), encodedIntColumn(t, "id", 2))
+	missingTable := &MissingTableOptions{Checker: &fakeSchemaChecker{existing: map[string]bool{}}, Policy: MissingTablePolicyAutoCreate}
+
+	txns, err := pbBinlogToTxn(binlog, tracker, missingTable)
+	if err != nil {
+		t.Fatalf("pbBinlogToTxn returned error: %v", err)
+	}
+
+	// one synthetic CREATE TABLE txn, deduped across both events for the
+	// same missing table, ahead of the single DML txn carrying both inserts.
+	if len(txns) != 2 {
+		t.Fatalf("got %d txns, want 2 (1 auto-create DDL + 1 DML txn)", len(txns))
+	}
+	if txns[0].DDL == nil || txns[0].DDL.Table != "t1" {
+		t.Fatalf("txns[0] = %+v, want a synthetic CREATE TABLE DDL for t1", txns[0])
+	}
+	if len(txns[1].DMLs) != 2 {
+		t.Fatalf("DML txn carries %d DMLs, want 2 (auto-create must not be emitted per event)", len(txns[1].DMLs))
+	}
+}
+
+// recordingDMLHandler is an EventHandler that records every OnInsert call it
+// receives, for Dispatch-level assertions that MissingTablePolicySkip really
+// drops the DML before it reaches the sink.
+type recordingDMLHandler struct {
+	DummyEventHandler
+
+	inserts []*loader.DML
+}
+
+func (h *recordingDMLHandler) OnInsert(dml *loader.DML, commitTS int64) error {
+	h.inserts = append(h.inserts, dml)
+	return nil
+}
+
+func TestDispatchSkipsMissingTableDML(t *testing.T) {
+	handler := &recordingDMLHandler{}
+	binlog := &pb.Binlog{
+		Tp:       pb.BinlogType_DML,
+		DmlData:  &pb.DMLData{Events: []*pb.Event{{SchemaName: "test", TableName: "missing", Tp: pb.EventType_Insert}}},
+		CommitTs: 1,
+	}
+	missingTable := &MissingTableOptions{Checker: &fakeSchemaChecker{existing: map[string]bool{}}, Policy: MissingTablePolicySkip}
+
+	if err := Dispatch(binlog, nil, missingTable, handler, nil); err != nil {
+		t.Fatalf("Dispatch returned error: %v", err)
+	}
+	if len(handler.inserts) != 0 {
+		t.Fatalf("handler recorded %d OnInsert calls, want 0 (DML for a missing table must be dropped)", len(handler.inserts))
+	}
+}
@@ -0,0 +1,34 @@
+package syncer
+
+// RowChangeOp identifies the kind of change a RowChange describes, using
+// the same vocabulary as BR's old cdclog format so existing Kafka
+// Connect/Flink consumers of that format can read tidb-binlog's output too.
+type RowChangeOp string
+
+// The row-change operation types a RowChangeEncoder can emit.
+const (
+	RowChangeInsert RowChangeOp = "INSERT"
+	RowChangeUpdate RowChangeOp = "UPDATE"
+	RowChangeDelete RowChangeOp = "DELETE"
+)
+
+// RowChange is a self-describing record of a single row-level DML event: it
+// carries enough information (operation, schema/table, commit-ts, the row's
+// state before and after the change, and column types) for a downstream
+// consumer to reconstruct the change without replaying the original binlog
+// stream or holding any upstream schema state of its own.
+type RowChange struct {
+	Op          RowChangeOp            `json:"op"`
+	Schema      string                 `json:"schema"`
+	Table       string                 `json:"table"`
+	CommitTS    int64                  `json:"commit_ts"`
+	PreImage    map[string]interface{} `json:"pre_image,omitempty"`
+	PostImage   map[string]interface{} `json:"post_image,omitempty"`
+	ColumnTypes map[string]string      `json:"column_types,omitempty"`
+}
+
+// Subject is the schema-registry-compatible subject name downstream Kafka
+// Connect/Flink consumers key their auto-evolving readers on.
+func (rc *RowChange) Subject() string {
+	return rc.Schema + "." + rc.Table
+}
@@ -0,0 +1,34 @@
+package syncer
+
+import "encoding/json"
+
+// RowChangeEncoder serializes a RowChange into the wire/file format a
+// cdclog sink writes, e.g. one JSON object per line or one Avro record.
+// Encode's result is appended to the writer's stream as-is, with no
+// separator added by the writer, so the encoder itself is responsible for
+// making record boundaries unambiguous (e.g. a trailing newline for
+// line-delimited JSON, a length prefix for binary Avro).
+type RowChangeEncoder interface {
+	// Encode returns the fully framed serialized form of rc, ready to be
+	// appended directly to a cdclogWriter's stream.
+	Encode(rc *RowChange) ([]byte, error)
+	// FileExtension is appended to rotated output file names, e.g. ".json".
+	FileExtension() string
+}
+
+// JSONEncoder encodes a RowChange as a single newline-terminated JSON
+// object, so concatenating records produces standard newline-delimited
+// JSON.
+type JSONEncoder struct{}
+
+// Encode implements RowChangeEncoder.
+func (JSONEncoder) Encode(rc *RowChange) ([]byte, error) {
+	data, err := json.Marshal(rc)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// FileExtension implements RowChangeEncoder.
+func (JSONEncoder) FileExtension() string { return ".json" }
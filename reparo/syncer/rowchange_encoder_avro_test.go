@@ -0,0 +1,129 @@
+package syncer
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+func TestAvroEncoderEncodeRoundTrip(t *testing.T) {
+	enc := NewAvroEncoder()
+	rc := &RowChange{
+		Op:          RowChangeUpdate,
+		Schema:      "test",
+		Table:       "t1",
+		CommitTS:    123,
+		PreImage:    map[string]interface{}{"id": "1", "v": "old"},
+		PostImage:   map[string]interface{}{"id": "1", "v": "new"},
+		ColumnTypes: map[string]string{"id": "int", "v": "varchar"},
+	}
+
+	data, err := enc.Encode(rc)
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	record := decodeLengthPrefixed(t, data)
+
+	codec, err := enc.codecFor(rc)
+	if err != nil {
+		t.Fatalf("codecFor returned error: %v", err)
+	}
+
+	native, _, err := codec.NativeFromBinary(record)
+	if err != nil {
+		t.Fatalf("NativeFromBinary returned error: %v", err)
+	}
+
+	decoded, ok := native.(map[string]interface{})
+	if !ok {
+		t.Fatalf("decoded native value is %T, want map[string]interface{}", native)
+	}
+	if decoded["table"] != "t1" {
+		t.Errorf("decoded table = %v, want t1", decoded["table"])
+	}
+}
+
+// TestAvroEncoderEncodeFramesConcatenatedRecords guards against the bug
+// where cdclogWriter appended a literal "\n" after every record: Avro binary
+// is arbitrary bytes and can itself contain 0x0A, which would corrupt
+// newline-delimited framing. Encode's length prefix must let two
+// concatenated records be split back apart regardless of their content.
+func TestAvroEncoderEncodeFramesConcatenatedRecords(t *testing.T) {
+	enc := NewAvroEncoder()
+	rc := &RowChange{
+		Op:          RowChangeInsert,
+		Schema:      "test",
+		Table:       "t1",
+		CommitTS:    1,
+		PostImage:   map[string]interface{}{"v": "line one\nline two"},
+		ColumnTypes: map[string]string{"v": "varchar"},
+	}
+
+	first, err := enc.Encode(rc)
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	second, err := enc.Encode(rc)
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	concatenated := append(append([]byte{}, first...), second...)
+
+	gotFirst := decodeLengthPrefixed(t, concatenated)
+	if len(gotFirst) != len(first)-4 {
+		t.Fatalf("first record length = %d, want %d", len(gotFirst), len(first)-4)
+	}
+	gotSecond := decodeLengthPrefixed(t, concatenated[4+len(gotFirst):])
+	if len(gotSecond) != len(second)-4 {
+		t.Fatalf("second record length = %d, want %d", len(gotSecond), len(second)-4)
+	}
+}
+
+// decodeLengthPrefixed reads AvroEncoder's 4-byte big-endian length prefix
+// off data and returns the record bytes that follow it.
+func decodeLengthPrefixed(t *testing.T, data []byte) []byte {
+	t.Helper()
+	if len(data) < 4 {
+		t.Fatalf("data too short for a length prefix: %d bytes", len(data))
+	}
+	n := binary.BigEndian.Uint32(data[:4])
+	if len(data) < int(4+n) {
+		t.Fatalf("data too short for a %d-byte record: %d bytes", n, len(data))
+	}
+	return data[4 : 4+n]
+}
+
+func TestAvroEncoderEncodeRejectsMissingColumnTypes(t *testing.T) {
+	enc := NewAvroEncoder()
+	rc := &RowChange{
+		Op:        RowChangeInsert,
+		Schema:    "test",
+		Table:     "untracked",
+		CommitTS:  1,
+		PostImage: map[string]interface{}{"id": "1"},
+	}
+
+	// ColumnTypes is empty, as happens when the schema tracker hasn't seen
+	// this table yet (see CDCLogEventHandler.columnTypes): Encode must fail
+	// loudly rather than silently emit a schema with no row fields, which
+	// would have silently dropped every column in the image.
+	if _, err := enc.Encode(rc); err == nil {
+		t.Fatal("Encode with empty ColumnTypes and non-empty PostImage returned no error, want one")
+	}
+}
+
+func TestAvroSchemaPreAndPostImageNamesDiffer(t *testing.T) {
+	schema, err := avroSchema("test.t1", map[string]string{"id": "int"})
+	if err != nil {
+		t.Fatalf("avroSchema returned error: %v", err)
+	}
+
+	// goavro rejects a schema that defines the same named record twice;
+	// constructing the codec is the real regression test for that.
+	if _, err := goavro.NewCodec(schema); err != nil {
+		t.Fatalf("goavro.NewCodec rejected schema with distinct pre/post image record names: %v\nschema: %s", err, schema)
+	}
+}
@@ -1,42 +1,101 @@
 package syncer
 
 import (
+	"strings"
+
 	"github.com/ngaut/log"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/parser"
 	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/format"
 	"github.com/pingcap/tidb-binlog/pkg/loader"
 	pb "github.com/pingcap/tidb-binlog/proto/binlog"
+	"github.com/pingcap/tidb-binlog/reparo/schema"
 	"github.com/pingcap/tidb/util/codec"
 )
 
-func pbBinlogToTxn(binlog *pb.Binlog) (txn *loader.Txn, err error) {
-	txn = new(loader.Txn)
+// pbBinlogToTxn converts a single pb.Binlog into one or more loader.Txn.
+// Most binlogs produce exactly one txn; a DDL that names more than one
+// table (e.g. `DROP TABLE a, b, c`, `RENAME TABLE a TO b, c TO d`) produces
+// one txn per affected table, each carrying its own correctly-qualified
+// loader.DDL, so downstream routing/filtering and schema-tracker
+// invalidation stay correct per table.
+//
+// tracker may be nil, in which case DML column types are decoded solely
+// from the pb.Column metadata the upstream shipped, same as before the
+// schema tracker was introduced; when non-nil it's kept in sync with every
+// DDL and used to enrich DML decoding.
+//
+// missingTable may be nil to disable the check entirely; otherwise it's
+// consulted for every DML event and MissingTablePolicy applied when
+// missingTable.Checker reports the downstream has no counterpart table.
+func pbBinlogToTxn(binlog *pb.Binlog, tracker *schema.Tracker, missingTable *MissingTableOptions) (txns []*loader.Txn, err error) {
 	switch binlog.Tp {
 	case pb.BinlogType_DDL:
-		txn.DDL = new(loader.DDL)
 		// for table DDL, pb.Binlog.DdlQuery will be "use <db>; create..."
-		txn.DDL.SQL = string(binlog.DdlQuery)
-		txn.DDL.Database, txn.DDL.Table, err = parserSchemaTableFromDDL(txn.DDL.SQL)
+		sql := string(binlog.DdlQuery)
+		tables, err := parseSchemaTablesFromDDL(sql)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		if len(txn.DDL.Database) == 0 {
+		if len(tables[0].Schema) == 0 {
 			return nil, errors.Errorf("can't parse database name from DDL %s", binlog.DdlQuery)
 		}
+
+		for _, st := range tables {
+			ddl := &loader.DDL{SQL: st.SQL, Database: st.Schema, Table: st.Table}
+
+			if tracker != nil {
+				if err := tracker.ApplyDDL(ddl.Database, ddl.SQL); err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+
+			txns = append(txns, &loader.Txn{DDL: ddl})
+		}
 	case pb.BinlogType_DML:
+		txn := new(loader.Txn)
+
+		// autoCreateTxns holds synthetic CREATE TABLE txns, queued ahead of
+		// txn so the downstream gets the table before the DML that needs
+		// it. autoCreated dedupes them: a burst of DMLs against the same
+		// missing table must only emit its CREATE TABLE once.
+		var autoCreateTxns []*loader.Txn
+		autoCreated := make(map[string]bool)
+
 		data := binlog.DmlData
 		for _, event := range data.GetEvents() {
+			database := event.GetSchemaName()
+			table := event.GetTableName()
+
+			if missingTable != nil && missingTable.Checker != nil && !missingTable.Checker.TableExists(database, table) {
+				switch missingTable.Policy {
+				case MissingTablePolicySkip:
+					continue
+				case MissingTablePolicyAutoCreate:
+					if !autoCreated[database+"."+table] {
+						ddlTxn, err := autoCreateDDL(tracker, database, table)
+						if err != nil {
+							return nil, errors.Trace(err)
+						}
+						autoCreateTxns = append(autoCreateTxns, ddlTxn)
+						autoCreated[database+"."+table] = true
+					}
+				default:
+					return nil, errors.Annotatef(errDownstreamTableNotFound, "%s.%s", database, table)
+				}
+			}
+
 			dml := new(loader.DML)
-			dml.Database = event.GetSchemaName()
-			dml.Table = event.GetTableName()
+			dml.Database = database
+			dml.Table = table
 			txn.DMLs = append(txn.DMLs, dml)
 
 			switch event.GetTp() {
 			case pb.EventType_Insert:
 				dml.Tp = loader.InsertDMLType
 
-				cols, args, err := genColsAndArgs(event.Row)
+				cols, args, err := genColsAndArgs(dml.Database, dml.Table, event.Row, tracker)
 				if err != nil {
 					return nil, errors.Trace(err)
 				}
@@ -67,6 +126,16 @@ func pbBinlogToTxn(binlog *pb.Binlog) (txn *loader.Txn, err error) {
 					}
 
 					tp := col.Tp[0]
+					if tracker != nil {
+						if trackedCols, trackErr := tracker.Columns(dml.Database, dml.Table); trackErr == nil {
+							for _, tc := range trackedCols {
+								if tc.Name.O == col.Name {
+									tp = byte(tc.GetType())
+									break
+								}
+							}
+						}
+					}
 					newDatum = formatValue(newDatum, tp)
 					newValue := newDatum.GetValue()
 					oldDatum = formatValue(oldDatum, tp)
@@ -80,7 +149,7 @@ func pbBinlogToTxn(binlog *pb.Binlog) (txn *loader.Txn, err error) {
 			case pb.EventType_Delete:
 				dml.Tp = loader.DeleteDMLType
 
-				cols, args, err := genColsAndArgs(event.Row)
+				cols, args, err := genColsAndArgs(dml.Database, dml.Table, event.Row, tracker)
 				if err != nil {
 					return nil, errors.Trace(err)
 				}
@@ -93,6 +162,9 @@ func pbBinlogToTxn(binlog *pb.Binlog) (txn *loader.Txn, err error) {
 				return nil, errors.Errorf("unknown type: %v", event.GetTp())
 			}
 		}
+
+		txns = append(txns, autoCreateTxns...)
+		txns = append(txns, txn)
 	default:
 		return nil, errors.Errorf("unknown type: %v", binlog.Tp)
 	}
@@ -100,7 +172,29 @@ func pbBinlogToTxn(binlog *pb.Binlog) (txn *loader.Txn, err error) {
 	return
 }
 
-func genColsAndArgs(row [][]byte) (cols []string, args []interface{}, err error) {
+// autoCreateDDL builds a synthetic loader.Txn carrying the CREATE TABLE
+// tracker has cached for database.table, for MissingTablePolicyAutoCreate to
+// replay downstream before the DML that needs the table.
+func autoCreateDDL(tracker *schema.Tracker, database, table string) (*loader.Txn, error) {
+	if tracker == nil {
+		return nil, errors.Annotatef(errDownstreamTableNotFound, "%s.%s (no schema tracker to auto-create from)", database, table)
+	}
+
+	sql, err := tracker.ShowCreateTable(database, table)
+	if err != nil {
+		return nil, errors.Annotatef(err, "auto-create %s.%s downstream", database, table)
+	}
+
+	return &loader.Txn{DDL: &loader.DDL{SQL: sql, Database: database, Table: table}}, nil
+}
+
+// genColsAndArgs decodes a DML event's row into parallel column name/value
+// slices. When tracker is non-nil and already knows about database.table,
+// its column definitions are consulted so that a column's type reflects the
+// latest DDL rather than whatever the pump happened to ship on this event;
+// this matters most for columns added or retyped after the event's txn
+// began replicating.
+func genColsAndArgs(database, table string, row [][]byte, tracker *schema.Tracker) (cols []string, args []interface{}, err error) {
 	cols = make([]string, 0, len(row))
 	args = make([]interface{}, 0, len(row))
 	for _, c := range row {
@@ -117,6 +211,17 @@ func genColsAndArgs(row [][]byte) (cols []string, args []interface{}, err error)
 		}
 
 		tp := col.Tp[0]
+		if tracker != nil {
+			if trackedCols, trackErr := tracker.Columns(database, table); trackErr == nil {
+				for _, tc := range trackedCols {
+					if tc.Name.O == col.Name {
+						tp = byte(tc.GetType())
+						break
+					}
+				}
+			}
+		}
+
 		val = formatValue(val, tp)
 		log.Debugf("%s(%s): %v", col.Name, col.MysqlType, val.GetValue())
 		args = append(args, val.GetValue())
@@ -125,75 +230,120 @@ func genColsAndArgs(row [][]byte) (cols []string, args []interface{}, err error)
 	return
 }
 
-// parserSchemaTableFromDDL parses ddl query to get schema and table
-// ddl like `use test; create table`
-func parserSchemaTableFromDDL(ddlQuery string) (schema, table string, err error) {
+// schemaTable is one (schema, table) pair affected by a DDL statement, along
+// with the single-table SQL that applies just that pair.
+type schemaTable struct {
+	Schema string
+	Table  string
+	SQL    string
+}
+
+// parseSchemaTablesFromDDL parses a ddl query (like `use test; create
+// table`) and returns every (schema, table) pair it affects, each paired
+// with the exact SQL to execute for that pair alone. For every statement
+// other than DROP TABLE and RENAME TABLE, st.SQL is just ddlQuery
+// unchanged. DROP TABLE and RENAME TABLE (e.g. `DROP TABLE a, b, c`,
+// `RENAME TABLE a TO x, b TO y`) yield one pair per table/pair listed, each
+// rewritten to a single-table statement with a leading `use`, even when only
+// one table is listed, so replaying entry N doesn't re-run (and fail on)
+// table 1..N-1.
+func parseSchemaTablesFromDDL(ddlQuery string) (tables []schemaTable, err error) {
 	stmts, _, err := parser.New().Parse(ddlQuery, "", "")
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
 	haveUseStmt := false
+	var db string
 
 	for _, stmt := range stmts {
 		switch node := stmt.(type) {
 		case *ast.UseStmt:
 			haveUseStmt = true
-			schema = node.DBName
+			db = node.DBName
 		case *ast.CreateDatabaseStmt:
-			schema = node.Name
+			db = node.Name
 		case *ast.DropDatabaseStmt:
-			schema = node.Name
+			db = node.Name
 		case *ast.TruncateTableStmt:
-			if len(node.Table.Schema.O) != 0 {
-				schema = node.Table.Schema.O
-			}
-			table = node.Table.Name.O
+			tables = append(tables, schemaTable{schemaOf(db, node.Table.Schema.O), node.Table.Name.O, ddlQuery})
 		case *ast.CreateIndexStmt:
-			if len(node.Table.Schema.O) != 0 {
-				schema = node.Table.Schema.O
-			}
-			table = node.Table.Name.O
+			tables = append(tables, schemaTable{schemaOf(db, node.Table.Schema.O), node.Table.Name.O, ddlQuery})
 		case *ast.CreateTableStmt:
-			if len(node.Table.Schema.O) != 0 {
-				schema = node.Table.Schema.O
-			}
-			table = node.Table.Name.O
+			tables = append(tables, schemaTable{schemaOf(db, node.Table.Schema.O), node.Table.Name.O, ddlQuery})
 		case *ast.DropIndexStmt:
-			if len(node.Table.Schema.O) != 0 {
-				schema = node.Table.Schema.O
-			}
-			table = node.Table.Name.O
+			tables = append(tables, schemaTable{schemaOf(db, node.Table.Schema.O), node.Table.Name.O, ddlQuery})
 		case *ast.AlterTableStmt:
-			if len(node.Table.Schema.O) != 0 {
-				schema = node.Table.Schema.O
-			}
-			table = node.Table.Name.O
+			tables = append(tables, schemaTable{schemaOf(db, node.Table.Schema.O), node.Table.Name.O, ddlQuery})
 		case *ast.DropTableStmt:
-			// FIXME: may drop more than one table in a ddl
-			if len(node.Tables[0].Schema.O) != 0 {
-				schema = node.Tables[0].Schema.O
+			for i, t := range node.Tables {
+				single := &ast.DropTableStmt{IfExists: node.IfExists, IsView: node.IsView, Tables: node.Tables[i : i+1]}
+				singleSQL, err := restoreStmt(single)
+				if err != nil {
+					return nil, errors.Annotatef(err, "restoring single-table DROP TABLE for %s", t.Name.O)
+				}
+				tables = append(tables, schemaTable{schemaOf(db, t.Schema.O), t.Name.O, useDBPrefix(db, singleSQL)})
 			}
-			table = node.Tables[0].Name.O
 		case *ast.RenameTableStmt:
-			if len(node.NewTable.Schema.O) != 0 {
-				schema = node.NewTable.Schema.O
+			for i, t := range node.TableToTables {
+				single := &ast.RenameTableStmt{TableToTables: node.TableToTables[i : i+1]}
+				singleSQL, err := restoreStmt(single)
+				if err != nil {
+					return nil, errors.Annotatef(err, "restoring single-table RENAME TABLE for %s", t.NewTable.Name.O)
+				}
+				tables = append(tables, schemaTable{schemaOf(db, t.NewTable.Schema.O), t.NewTable.Name.O, useDBPrefix(db, singleSQL)})
 			}
-			table = node.NewTable.Name.O
 		default:
-			return "", "", errors.Errorf("unknown ddl type, ddl: %s", ddlQuery)
+			return nil, errors.Errorf("unknown ddl type, ddl: %s", ddlQuery)
 		}
 	}
 
 	if haveUseStmt {
 		if len(stmts) != 2 {
-			return "", "", errors.Errorf("invalid ddl %s", ddlQuery)
+			return nil, errors.Errorf("invalid ddl %s", ddlQuery)
 		}
 	} else {
 		if len(stmts) != 1 {
-			return "", "", errors.Errorf("invalid ddl %s", ddlQuery)
+			return nil, errors.Errorf("invalid ddl %s", ddlQuery)
 		}
 	}
 
-	return
+	if len(tables) == 0 {
+		// CREATE/DROP DATABASE: no table, but still report the schema so
+		// callers can tell the DDL applies to db as a whole.
+		tables = append(tables, schemaTable{Schema: db, SQL: ddlQuery})
+	}
+
+	return tables, nil
+}
+
+// restoreStmt renders stmt back to SQL text, used to turn the single-table
+// AST nodes parseSchemaTablesFromDDL synthesizes for each table of a DROP
+// TABLE/RENAME TABLE back into executable SQL.
+func restoreStmt(stmt ast.StmtNode) (string, error) {
+	var sb strings.Builder
+	if err := stmt.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return "", errors.Trace(err)
+	}
+	return sb.String(), nil
+}
+
+// useDBPrefix prepends a `use `db`;` statement to sql, so a synthesized
+// single-table statement is self-contained and selects its own schema like
+// every other schemaTable.SQL does (all derived from the original ddlQuery,
+// which pb.Binlog.DdlQuery always ships as "use <db>; <ddl>").
+func useDBPrefix(db, sql string) string {
+	if db == "" {
+		return sql
+	}
+	return "use `" + db + "`; " + sql
+}
+
+// schemaOf prefers a table's own schema qualifier (`db.table`) over the
+// database named in a preceding `use` statement.
+func schemaOf(useDB, tableSchema string) string {
+	if len(tableSchema) != 0 {
+		return tableSchema
+	}
+	return useDB
 }
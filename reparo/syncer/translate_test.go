@@ -0,0 +1,104 @@
+package syncer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSchemaTablesFromDDL(t *testing.T) {
+	cases := []struct {
+		ddl    string
+		tables []schemaTable
+	}{
+		{
+			ddl: "use `test`; create table `t1` (`id` int primary key)",
+			tables: []schemaTable{
+				{Schema: "test", Table: "t1"},
+			},
+		},
+		{
+			ddl: "use `test`; drop table `a`, `b`, `c`",
+			tables: []schemaTable{
+				{Schema: "test", Table: "a"},
+				{Schema: "test", Table: "b"},
+				{Schema: "test", Table: "c"},
+			},
+		},
+		{
+			ddl: "use `test`; rename table `a` to `x`, `b` to `y`",
+			tables: []schemaTable{
+				{Schema: "test", Table: "x"},
+				{Schema: "test", Table: "y"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		got, err := parseSchemaTablesFromDDL(c.ddl)
+		if err != nil {
+			t.Fatalf("parseSchemaTablesFromDDL(%q) returned error: %v", c.ddl, err)
+		}
+		if len(got) != len(c.tables) {
+			t.Fatalf("parseSchemaTablesFromDDL(%q) = %d tables, want %d", c.ddl, len(got), len(c.tables))
+		}
+		for i, want := range c.tables {
+			if got[i].Schema != want.Schema || got[i].Table != want.Table {
+				t.Errorf("parseSchemaTablesFromDDL(%q)[%d] = {%s, %s}, want {%s, %s}",
+					c.ddl, i, got[i].Schema, got[i].Table, want.Schema, want.Table)
+			}
+			if got[i].SQL == "" {
+				t.Errorf("parseSchemaTablesFromDDL(%q)[%d].SQL is empty", c.ddl, i)
+			}
+		}
+	}
+}
+
+func TestParseSchemaTablesFromDDLDropTableSingleTableSQL(t *testing.T) {
+	got, err := parseSchemaTablesFromDDL("use `test`; drop table `a`, `b`")
+	if err != nil {
+		t.Fatalf("parseSchemaTablesFromDDL returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d tables, want 2", len(got))
+	}
+
+	// each fanned-out entry must only mention its own table, so replaying
+	// entry N doesn't re-drop (and fail on) a table an earlier entry already
+	// removed.
+	if got[0].SQL == got[1].SQL {
+		t.Fatalf("fanned-out DROP TABLE entries must not share identical SQL, got %q for both", got[0].SQL)
+	}
+	for _, st := range got {
+		other := "a"
+		if st.Table == "a" {
+			other = "b"
+		}
+		if strings.Contains(st.SQL, other) {
+			t.Errorf("single-table SQL %q for table %q must not mention table %q", st.SQL, st.Table, other)
+		}
+	}
+}
+
+func TestParseSchemaTablesFromDDLFannedSQLSelectsOwnSchema(t *testing.T) {
+	cases := []string{
+		"use `test`; drop table `a`, `b`",
+		"use `test`; rename table `a` to `x`, `b` to `y`",
+	}
+
+	for _, ddl := range cases {
+		got, err := parseSchemaTablesFromDDL(ddl)
+		if err != nil {
+			t.Fatalf("parseSchemaTablesFromDDL(%q) returned error: %v", ddl, err)
+		}
+		for _, st := range got {
+			// every schemaTable.SQL must be self-contained and select its
+			// own schema, same as the single-table branches that reuse
+			// ddlQuery (which always carries a leading `use`), so the
+			// downstream loader doesn't depend on whatever database an
+			// earlier statement happened to leave selected.
+			if !strings.HasPrefix(st.SQL, "use `"+st.Schema+"`;") {
+				t.Errorf("parseSchemaTablesFromDDL(%q): SQL %q for table %q doesn't start with its own use statement", ddl, st.SQL, st.Table)
+			}
+		}
+	}
+}